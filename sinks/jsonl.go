@@ -0,0 +1,72 @@
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/RyonMcAuley/Twitch-Chat-Analytics/twitchbot"
+)
+
+//JSONLSink appends one JSON-encoded Event per line to a file in dir, rotating to a new
+//file named "chat-YYYY-MM-DD.jsonl" whenever an Event's date differs from the currently
+//open file's.
+type JSONLSink struct {
+	dir string
+
+	mu      sync.Mutex
+	day     string
+	file    *os.File
+	encoder *json.Encoder
+}
+
+//NewJSONLSink returns a JSONLSink that writes into dir, creating it if it doesn't exist
+func NewJSONLSink(dir string) (*JSONLSink, error) {
+	if err := os.MkdirAll(dir, 0o755); nil != err {
+		return nil, err
+	}
+	return &JSONLSink{dir: dir}, nil
+}
+
+//Write appends ev as a single JSON line, rotating to the day's file first if necessary
+func (s *JSONLSink) Write(ev twitchbot.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := ev.Time.Format("2006-01-02")
+	if day != s.day {
+		if err := s.rotate(day); nil != err {
+			return err
+		}
+	}
+	return s.encoder.Encode(ev)
+}
+
+//rotate closes the currently open file, if any, and opens (or creates) the file for day.
+//Callers must hold s.mu.
+func (s *JSONLSink) rotate(day string) error {
+	if nil != s.file {
+		s.file.Close()
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("chat-%s.jsonl", day))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if nil != err {
+		return err
+	}
+	s.file = f
+	s.encoder = json.NewEncoder(f)
+	s.day = day
+	return nil
+}
+
+//Close closes the currently open file, if any
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if nil == s.file {
+		return nil
+	}
+	return s.file.Close()
+}
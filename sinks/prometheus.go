@@ -0,0 +1,108 @@
+package sinks
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/RyonMcAuley/Twitch-Chat-Analytics/twitchbot"
+)
+
+//PrometheusSink exposes chat activity as Prometheus metrics on "/metrics"
+type PrometheusSink struct {
+	messagesTotal   *prometheus.CounterVec
+	subsTotal       *prometheus.CounterVec
+	bitsTotal       *prometheus.CounterVec
+	modActionsTotal *prometheus.CounterVec
+	uniqueChatters  *prometheus.GaugeVec
+
+	mu     sync.Mutex
+	seen   map[string]map[string]struct{} //channel -> set of user names seen, for uniqueChatters
+	server *http.Server
+}
+
+//NewPrometheusSink registers its collectors and starts serving "/metrics" on addr
+func NewPrometheusSink(addr string) (*PrometheusSink, error) {
+	s := &PrometheusSink{
+		messagesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "twitchbot_messages_total",
+			Help: "Chat messages seen, by channel.",
+		}, []string{"channel"}),
+		subsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "twitchbot_subs_total",
+			Help: "Sub events seen, by channel and sub plan.",
+		}, []string{"channel", "plan"}),
+		bitsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "twitchbot_bits_total",
+			Help: "Bits cheered, by channel.",
+		}, []string{"channel"}),
+		modActionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "twitchbot_mod_actions_total",
+			Help: "Timeouts and bans issued, by channel.",
+		}, []string{"channel"}),
+		//tracked as a plain set rather than an HLL sketch; fine at this sink's scale and
+		//exact rather than approximate
+		uniqueChatters: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "twitchbot_unique_chatters",
+			Help: "Distinct chatters seen since startup, by channel.",
+		}, []string{"channel"}),
+		seen: make(map[string]map[string]struct{}),
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(s.messagesTotal, s.subsTotal, s.bitsTotal, s.modActionsTotal, s.uniqueChatters)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	s.server = &http.Server{Addr: addr, Handler: mux}
+	go s.server.ListenAndServe()
+
+	return s, nil
+}
+
+//Write updates the relevant counters for ev
+func (s *PrometheusSink) Write(ev twitchbot.Event) error {
+	switch ev.Type {
+	case twitchbot.EventTypeMessage:
+		if nil == ev.Message {
+			return nil
+		}
+		s.messagesTotal.WithLabelValues(ev.Message.Channel).Inc()
+		s.trackChatter(ev.Message.Channel, ev.Message.UserName)
+	case twitchbot.EventTypeSub:
+		if nil == ev.Sub {
+			return nil
+		}
+		s.subsTotal.WithLabelValues(ev.Sub.Channel, ev.Sub.SubPlan).Inc()
+	case twitchbot.EventTypeCheer:
+		if nil == ev.Cheer {
+			return nil
+		}
+		s.bitsTotal.WithLabelValues(ev.Cheer.Channel).Add(float64(ev.Cheer.Bits))
+	case twitchbot.EventTypeTimeout:
+		if nil == ev.Timeout {
+			return nil
+		}
+		s.modActionsTotal.WithLabelValues(ev.Timeout.Channel).Inc()
+	}
+	return nil
+}
+
+//trackChatter records that userName has chatted in channel and updates that channel's
+//uniqueChatters gauge
+func (s *PrometheusSink) trackChatter(channel, userName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if nil == s.seen[channel] {
+		s.seen[channel] = make(map[string]struct{})
+	}
+	s.seen[channel][userName] = struct{}{}
+	s.uniqueChatters.WithLabelValues(channel).Set(float64(len(s.seen[channel])))
+}
+
+//Close shuts down the metrics HTTP server
+func (s *PrometheusSink) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,121 @@
+package sinks
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/RyonMcAuley/Twitch-Chat-Analytics/twitchbot"
+)
+
+//SQLiteSink persists chat Events into a SQLite database: one table each for messages,
+//subs, cheers, and timeouts, plus a running per-user message counter.
+type SQLiteSink struct {
+	db *sql.DB
+}
+
+//NewSQLiteSink opens (creating if necessary) a SQLite database at path and ensures its
+//schema exists
+func NewSQLiteSink(path string) (*SQLiteSink, error) {
+	db, err := sql.Open("sqlite3", path)
+	if nil != err {
+		return nil, err
+	}
+	s := &SQLiteSink{db: db}
+	if err := s.migrate(); nil != err {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+//migrate creates the sink's tables if they don't already exist
+func (s *SQLiteSink) migrate() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS messages (
+			channel TEXT, user_name TEXT, text TEXT, bits INTEGER, tmi_sent_ts DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS subs (
+			channel TEXT, user_name TEXT, msg_id TEXT, cumulative_months INTEGER, streak_months INTEGER, tmi_sent_ts DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS cheers (
+			channel TEXT, user_name TEXT, bits INTEGER, tmi_sent_ts DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS timeouts (
+			channel TEXT, user_name TEXT, ban_duration INTEGER, tmi_sent_ts DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_counters (
+			channel TEXT NOT NULL, user_name TEXT NOT NULL, message_count INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (channel, user_name)
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+//Write persists ev into the table matching its Type, bumping the sender's running
+//message counter for EventTypeMessage
+func (s *SQLiteSink) Write(ev twitchbot.Event) error {
+	switch ev.Type {
+	case twitchbot.EventTypeMessage:
+		return s.writeMessage(ev)
+	case twitchbot.EventTypeSub:
+		return s.writeSub(ev)
+	case twitchbot.EventTypeCheer:
+		return s.writeCheer(ev)
+	case twitchbot.EventTypeTimeout:
+		return s.writeTimeout(ev)
+	default:
+		return nil
+	}
+}
+
+func (s *SQLiteSink) writeMessage(ev twitchbot.Event) error {
+	if nil == ev.Message {
+		return nil
+	}
+	if _, err := s.db.Exec(`INSERT INTO messages (channel, user_name, text, bits, tmi_sent_ts) VALUES (?, ?, ?, ?, ?)`,
+		ev.Message.Channel, ev.Message.UserName, ev.Message.Text, ev.Message.Bits, ev.Message.TmiSentTS); nil != err {
+		return err
+	}
+	_, err := s.db.Exec(`INSERT INTO user_counters (channel, user_name, message_count) VALUES (?, ?, 1)
+		ON CONFLICT(channel, user_name) DO UPDATE SET message_count = message_count + 1`,
+		ev.Message.Channel, ev.Message.UserName)
+	return err
+}
+
+func (s *SQLiteSink) writeSub(ev twitchbot.Event) error {
+	if nil == ev.Sub {
+		return nil
+	}
+	_, err := s.db.Exec(`INSERT INTO subs (channel, user_name, msg_id, cumulative_months, streak_months, tmi_sent_ts) VALUES (?, ?, ?, ?, ?, ?)`,
+		ev.Sub.Channel, ev.Sub.UserName, ev.Sub.MsgID, ev.Sub.CumulativeMonths, ev.Sub.StreakMonths, ev.Time)
+	return err
+}
+
+func (s *SQLiteSink) writeCheer(ev twitchbot.Event) error {
+	if nil == ev.Cheer {
+		return nil
+	}
+	_, err := s.db.Exec(`INSERT INTO cheers (channel, user_name, bits, tmi_sent_ts) VALUES (?, ?, ?, ?)`,
+		ev.Cheer.Channel, ev.Cheer.UserName, ev.Cheer.Bits, ev.Time)
+	return err
+}
+
+func (s *SQLiteSink) writeTimeout(ev twitchbot.Event) error {
+	if nil == ev.Timeout {
+		return nil
+	}
+	_, err := s.db.Exec(`INSERT INTO timeouts (channel, user_name, ban_duration, tmi_sent_ts) VALUES (?, ?, ?, ?)`,
+		ev.Timeout.Channel, ev.Timeout.UserName, ev.Timeout.BanDuration, ev.Time)
+	return err
+}
+
+//Close closes the underlying database handle
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
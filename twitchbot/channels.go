@@ -0,0 +1,124 @@
+package twitchbot
+
+import (
+	"fmt"
+	"time"
+)
+
+//ChannelState tracks a joined channel's ROOMSTATE settings and when the bot joined it
+type ChannelState struct {
+	RoomID        string
+	SlowMode      int //seconds required between messages; 0 means disabled
+	FollowersOnly int //minutes of required following; -1 means disabled
+	R9K           bool
+	EmoteOnly     bool
+	SubsOnly      bool
+	JoinTime      time.Time
+}
+
+//channels returns the set of channels JoinChannel should join, falling back to the
+//single Channel field for callers that haven't migrated to Channels yet
+func (bb *BasicBot) channels() []string {
+	if len(bb.Channels) > 0 {
+		return bb.Channels
+	}
+	if "" != bb.Channel {
+		return []string{bb.Channel}
+	}
+	return nil
+}
+
+//joinedChannels returns the channels currently tracked as joined
+func (bb *BasicBot) joinedChannels() []string {
+	bb.channelStateMu.RLock()
+	defer bb.channelStateMu.RUnlock()
+	if 0 == len(bb.channelState) {
+		return nil
+	}
+	names := make([]string, 0, len(bb.channelState))
+	for name := range bb.channelState {
+		names = append(names, name)
+	}
+	return names
+}
+
+//rejoinTargets returns the channels JoinChannel should join: whatever is already
+//tracked as joined, so a reconnect rejoins everything even if Join was called
+//dynamically after startup, or bb.channels() on a first connect
+func (bb *BasicBot) rejoinTargets() []string {
+	if joined := bb.joinedChannels(); len(joined) > 0 {
+		return joined
+	}
+	return bb.channels()
+}
+
+//Join sends a JOIN for channel and starts tracking its ChannelState, blocking until the
+//bot-wide JOIN-rate bucket has a token available
+func (bb *BasicBot) Join(channel string) {
+	fmt.Printf("[%s] Joining #%s...\n", timeStamp(), channel)
+	bb.joinTokenBucket().Take()
+	bb.conn.Write([]byte("JOIN #" + channel + "\r\n"))
+
+	bb.channelStateMu.Lock()
+	if nil == bb.channelState {
+		bb.channelState = make(map[string]*ChannelState)
+	}
+	bb.channelState[channel] = &ChannelState{FollowersOnly: -1, JoinTime: time.Now()}
+	bb.channelStateMu.Unlock()
+
+	fmt.Printf("[%s] Joined #%s as @%s!\n", timeStamp(), channel, bb.Name)
+}
+
+//Part sends a PART for channel and stops tracking its ChannelState
+func (bb *BasicBot) Part(channel string) {
+	fmt.Printf("[%s] Leaving #%s...\n", timeStamp(), channel)
+	bb.conn.Write([]byte("PART #" + channel + "\r\n"))
+
+	bb.channelStateMu.Lock()
+	delete(bb.channelState, channel)
+	bb.channelStateMu.Unlock()
+}
+
+//ChannelState returns the tracked ROOMSTATE for channel, and whether it is currently known
+func (bb *BasicBot) ChannelState(channel string) (ChannelState, bool) {
+	bb.channelStateMu.RLock()
+	defer bb.channelStateMu.RUnlock()
+	cs, ok := bb.channelState[channel]
+	if !ok {
+		return ChannelState{}, false
+	}
+	return *cs, true
+}
+
+//updateChannelState applies a parsed ROOMSTATE to the tracked ChannelState for its channel,
+//preserving JoinTime and any fields a partial ROOMSTATE update left out
+func (bb *BasicBot) updateChannelState(rs *RoomState) {
+	bb.channelStateMu.Lock()
+	defer bb.channelStateMu.Unlock()
+	if nil == bb.channelState {
+		bb.channelState = make(map[string]*ChannelState)
+	}
+	cs, ok := bb.channelState[rs.Channel]
+	if !ok {
+		cs = &ChannelState{FollowersOnly: -1, JoinTime: time.Now()}
+		bb.channelState[rs.Channel] = cs
+	}
+	if _, ok := rs.Tags["room-id"]; ok {
+		cs.RoomID = rs.RoomID
+	}
+	if _, ok := rs.Tags["slow"]; ok {
+		cs.SlowMode = rs.Slow
+	}
+	if _, ok := rs.Tags["followers-only"]; ok {
+		cs.FollowersOnly = rs.FollowersOnly
+	}
+	if _, ok := rs.Tags["r9k"]; ok {
+		cs.R9K = rs.R9K
+	}
+	if _, ok := rs.Tags["emote-only"]; ok {
+		cs.EmoteOnly = rs.EmoteOnly
+	}
+	if _, ok := rs.Tags["subs-only"]; ok {
+		cs.SubsOnly = rs.SubsOnly
+	}
+}
@@ -0,0 +1,59 @@
+package twitchbot
+
+import "testing"
+
+//TestUpdateChannelStatePartialROOMSTATE verifies that a partial ROOMSTATE update (Twitch
+//only sends the tags that changed) merges in just those tags, leaving everything else on
+//the tracked ChannelState untouched.
+func TestUpdateChannelStatePartialROOMSTATE(t *testing.T) {
+	bb := &BasicBot{}
+
+	full := newRoomState(map[string]string{
+		"room-id":        "12345",
+		"slow":           "0",
+		"followers-only": "-1",
+		"r9k":            "0",
+		"emote-only":     "0",
+		"subs-only":      "0",
+	}, "somechannel")
+	bb.updateChannelState(full)
+
+	cs, ok := bb.ChannelState("somechannel")
+	if !ok {
+		t.Fatalf("channel state not tracked after initial ROOMSTATE")
+	}
+	if cs.RoomID != "12345" || cs.SlowMode != 0 || cs.FollowersOnly != -1 || cs.R9K || cs.EmoteOnly || cs.SubsOnly {
+		t.Fatalf("unexpected state after initial ROOMSTATE: %+v", cs)
+	}
+
+	//Twitch enabling subs-only mode sends only the "subs-only" tag
+	partial := newRoomState(map[string]string{"subs-only": "1"}, "somechannel")
+	bb.updateChannelState(partial)
+
+	cs, _ = bb.ChannelState("somechannel")
+	if !cs.SubsOnly {
+		t.Fatalf("subs-only update was not applied: %+v", cs)
+	}
+	if cs.RoomID != "12345" || cs.SlowMode != 0 || cs.FollowersOnly != -1 || cs.R9K || cs.EmoteOnly {
+		t.Fatalf("partial update clobbered fields it didn't touch: %+v", cs)
+	}
+
+	//Twitch enabling followers-only mode sends "followers-only=<minutes>"
+	partial = newRoomState(map[string]string{"followers-only": "10"}, "somechannel")
+	bb.updateChannelState(partial)
+
+	cs, _ = bb.ChannelState("somechannel")
+	if cs.FollowersOnly != 10 {
+		t.Fatalf("followers-only enable was not applied: %+v", cs)
+	}
+
+	//Twitch disabling followers-only mode sends "followers-only=-1", which must be applied
+	//as -1 (disabled) rather than treated like an absent tag
+	partial = newRoomState(map[string]string{"followers-only": "-1"}, "somechannel")
+	bb.updateChannelState(partial)
+
+	cs, _ = bb.ChannelState("somechannel")
+	if cs.FollowersOnly != -1 {
+		t.Fatalf("explicit followers-only disable was not applied: %+v", cs)
+	}
+}
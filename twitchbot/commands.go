@@ -0,0 +1,187 @@
+package twitchbot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+//commandRegex splits a chat message into a "!command" name and the rest of the line as its args
+var commandRegex *regexp.Regexp = regexp.MustCompile(`^!(\w+)\s?(.*)$`)
+
+//PermissionLevel gates who is allowed to invoke a registered command
+type PermissionLevel int
+
+//Permission levels are ordered low to high; a handler registered at a given level
+//may also be run by anyone at a higher level
+const (
+	PermEveryone PermissionLevel = iota
+	PermSubscriber
+	PermVIP
+	PermMod
+	PermBroadcaster
+)
+
+//CommandContext is passed to a registered command's handler
+type CommandContext struct {
+	//Bot is the bot the command was invoked on, so handlers can Say(), look up ChannelState, etc.
+	Bot *BasicBot
+	//Message is the PRIVMSG that triggered the command
+	Message *ChatMessage
+	//Command is the command name, without the leading "!"
+	Command string
+	//Args is whatever followed the command name on the line
+	Args string
+}
+
+//CmdOpt configures a registered command. See WithPermission, WithUserCooldown, WithChannelCooldown.
+type CmdOpt func(*commandConfig)
+
+//commandConfig holds a registered command's permission and cooldown settings
+type commandConfig struct {
+	perm            PermissionLevel
+	userCooldown    time.Duration
+	channelCooldown time.Duration
+}
+
+//WithPermission restricts a command to callers at or above the given PermissionLevel
+func WithPermission(perm PermissionLevel) CmdOpt {
+	return func(c *commandConfig) {
+		c.perm = perm
+	}
+}
+
+//WithUserCooldown rejects repeat invocations from the same user inside the given duration
+func WithUserCooldown(d time.Duration) CmdOpt {
+	return func(c *commandConfig) {
+		c.userCooldown = d
+	}
+}
+
+//WithChannelCooldown rejects any invocation of the command inside the given duration
+//of its last use in that channel, regardless of who sends it
+func WithChannelCooldown(d time.Duration) CmdOpt {
+	return func(c *commandConfig) {
+		c.channelCooldown = d
+	}
+}
+
+//registeredCommand pairs a handler with its config and cooldown bookkeeping
+type registeredCommand struct {
+	handler func(ctx CommandContext) error
+	config  commandConfig
+
+	mu              sync.Mutex
+	lastUsedByUser  map[string]time.Time
+	lastUsedChannel map[string]time.Time
+}
+
+//ErrOnCooldown is returned internally when a command is invoked before its cooldown expires
+var ErrOnCooldown = errors.New("twitchbot: command is on cooldown")
+
+//ErrPermissionDenied is returned internally when a caller lacks the permission a command requires
+var ErrPermissionDenied = errors.New("twitchbot: insufficient permission to run command")
+
+//ErrShutdown is returned by the built-in "!tbdown" command to tell HandleChat to return cleanly
+var ErrShutdown = errors.New("twitchbot: shutdown command received")
+
+//registerDefaultCommands registers the bot's built-in commands the first time it runs,
+//unless the embedder has already registered a command under the same name
+func (bb *BasicBot) registerDefaultCommands() {
+	bb.commandsMu.RLock()
+	_, exists := bb.commands["tbdown"]
+	bb.commandsMu.RUnlock()
+	if exists {
+		return
+	}
+	bb.RegisterCommand("tbdown", func(ctx CommandContext) error {
+		fmt.Printf("[%s] Shutdown command received. Shutting down now...\n", timeStamp())
+		ctx.Bot.Say(ctx.Message.Channel, "goodbye")
+		ctx.Bot.Disconnect()
+		return ErrShutdown
+	}, WithPermission(PermBroadcaster))
+}
+
+//RegisterCommand registers handler to run whenever a chat message starts with "!name".
+//By default a command is runnable by everyone with no cooldown; pass opts to restrict that.
+func (bb *BasicBot) RegisterCommand(name string, handler func(ctx CommandContext) error, opts ...CmdOpt) {
+	cfg := commandConfig{perm: PermEveryone}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	bb.commandsMu.Lock()
+	defer bb.commandsMu.Unlock()
+	if nil == bb.commands {
+		bb.commands = make(map[string]*registeredCommand)
+	}
+	bb.commands[name] = &registeredCommand{
+		handler:         handler,
+		config:          cfg,
+		lastUsedByUser:  make(map[string]time.Time),
+		lastUsedChannel: make(map[string]time.Time),
+	}
+}
+
+//dispatchCommand looks up and runs the command named in msg.Text, if any is registered.
+//It is a no-op (returning nil) when the message isn't a command invocation.
+func (bb *BasicBot) dispatchCommand(msg *ChatMessage) error {
+	matches := commandRegex.FindStringSubmatch(msg.Text)
+	if nil == matches {
+		return nil
+	}
+	name, args := matches[1], matches[2]
+
+	bb.commandsMu.RLock()
+	cmd, ok := bb.commands[name]
+	bb.commandsMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if permissionLevel(msg, msg.Channel) < cmd.config.perm {
+		return ErrPermissionDenied
+	}
+
+	cmd.mu.Lock()
+	now := time.Now()
+	if cmd.config.channelCooldown > 0 && now.Sub(cmd.lastUsedChannel[msg.Channel]) < cmd.config.channelCooldown {
+		cmd.mu.Unlock()
+		return ErrOnCooldown
+	}
+	if cmd.config.userCooldown > 0 && now.Sub(cmd.lastUsedByUser[msg.UserName]) < cmd.config.userCooldown {
+		cmd.mu.Unlock()
+		return ErrOnCooldown
+	}
+	cmd.lastUsedChannel[msg.Channel] = now
+	cmd.lastUsedByUser[msg.UserName] = now
+	cmd.mu.Unlock()
+
+	return cmd.handler(CommandContext{Bot: bb, Message: msg, Command: name, Args: args})
+}
+
+//permissionLevel determines the highest PermissionLevel a chat message's sender holds,
+//based on their badges and whether they own the channel
+func permissionLevel(msg *ChatMessage, channel string) PermissionLevel {
+	if msg.UserName == channel {
+		return PermBroadcaster
+	}
+	if _, ok := msg.Badges["broadcaster"]; ok {
+		return PermBroadcaster
+	}
+	if _, ok := msg.Badges["moderator"]; ok {
+		return PermMod
+	}
+	if _, ok := msg.Badges["vip"]; ok {
+		return PermVIP
+	}
+	if _, ok := msg.Badges["subscriber"]; ok {
+		return PermSubscriber
+	}
+	if _, ok := msg.Badges["founder"]; ok {
+		return PermSubscriber
+	}
+	return PermEveryone
+}
@@ -0,0 +1,77 @@
+package twitchbot
+
+//OnMessage registers fn to run for every parsed PRIVMSG
+func (bb *BasicBot) OnMessage(fn func(ChatMessage)) {
+	bb.onMessage = append(bb.onMessage, fn)
+}
+
+//OnSub registers fn to run for every sub, resub, and gift sub USERNOTICE
+func (bb *BasicBot) OnSub(fn func(SubEvent)) {
+	bb.onSub = append(bb.onSub, fn)
+}
+
+//OnRaid registers fn to run for every incoming raid
+func (bb *BasicBot) OnRaid(fn func(RaidEvent)) {
+	bb.onRaid = append(bb.onRaid, fn)
+}
+
+//OnCheer registers fn to run for every PRIVMSG that carried Bits
+func (bb *BasicBot) OnCheer(fn func(CheerEvent)) {
+	bb.onCheer = append(bb.onCheer, fn)
+}
+
+//OnTimeout registers fn to run for every CLEARCHAT targeting a single user
+func (bb *BasicBot) OnTimeout(fn func(TimeoutEvent)) {
+	bb.onTimeout = append(bb.onTimeout, fn)
+}
+
+//fireMessage runs every registered OnMessage handler, fans the message out to every
+//registered Sink, and, if the message has a command invocation or carries Bits,
+//dispatches the matching command and OnCheer handlers. It returns the command's error
+//unchanged so callers can special-case ErrShutdown.
+func (bb *BasicBot) fireMessage(msg *ChatMessage) error {
+	for _, fn := range bb.onMessage {
+		fn(*msg)
+	}
+	bb.fireEvent(Event{Type: EventTypeMessage, Channel: msg.Channel, Time: msg.TmiSentTS, Tags: msg.Tags, Message: msg})
+	if msg.Bits > 0 {
+		bb.fireCheer(&CheerEvent{Channel: msg.Channel, UserName: msg.UserName, Bits: msg.Bits, Message: msg})
+	}
+	return bb.dispatchCommand(msg)
+}
+
+//fireSub runs every registered OnSub handler and fans ev out to every registered Sink
+func (bb *BasicBot) fireSub(ev *SubEvent) {
+	for _, fn := range bb.onSub {
+		fn(*ev)
+	}
+	bb.fireEvent(Event{Type: EventTypeSub, Channel: ev.Channel, Time: parseTmiSentTS(ev.Tags["tmi-sent-ts"]), Tags: ev.Tags, Sub: ev})
+}
+
+//fireRaid runs every registered OnRaid handler and fans ev out to every registered Sink
+func (bb *BasicBot) fireRaid(ev *RaidEvent) {
+	for _, fn := range bb.onRaid {
+		fn(*ev)
+	}
+	bb.fireEvent(Event{Type: EventTypeRaid, Channel: ev.Channel, Time: parseTmiSentTS(ev.Tags["tmi-sent-ts"]), Tags: ev.Tags, Raid: ev})
+}
+
+//fireCheer runs every registered OnCheer handler and fans ev out to every registered Sink
+func (bb *BasicBot) fireCheer(ev *CheerEvent) {
+	for _, fn := range bb.onCheer {
+		fn(*ev)
+	}
+	var tags map[string]string
+	if nil != ev.Message {
+		tags = ev.Message.Tags
+	}
+	bb.fireEvent(Event{Type: EventTypeCheer, Channel: ev.Channel, Time: parseTmiSentTS(tags["tmi-sent-ts"]), Tags: tags, Cheer: ev})
+}
+
+//fireTimeout runs every registered OnTimeout handler and fans ev out to every registered Sink
+func (bb *BasicBot) fireTimeout(ev *TimeoutEvent) {
+	for _, fn := range bb.onTimeout {
+		fn(*ev)
+	}
+	bb.fireEvent(Event{Type: EventTypeTimeout, Channel: ev.Channel, Time: parseTmiSentTS(ev.Tags["tmi-sent-ts"]), Tags: ev.Tags, Timeout: ev})
+}
@@ -0,0 +1,347 @@
+package twitchbot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ChatMessage is a parsed PRIVMSG together with the IRCv3 tag metadata Twitch attaches to it
+type ChatMessage struct {
+	Channel     string
+	UserName    string
+	Text        string
+	DisplayName string
+	UserID      string
+	RoomID      string
+	Color       string
+	Badges      map[string]string
+	Bits        int
+	Emotes      []EmoteRange
+	TmiSentTS   time.Time
+	MsgID       string
+
+	//only set on messages sent using Twitch's reply feature
+	ReplyParentMsgID       string
+	ReplyParentUserID      string
+	ReplyParentUserLogin   string
+	ReplyParentDisplayName string
+	ReplyParentMsgBody     string
+
+	//Tags holds every tag Twitch sent, the fields above included, for anything not yet surfaced above
+	Tags map[string]string
+}
+
+//EmoteRange locates a single emote occurrence within ChatMessage.Text
+type EmoteRange struct {
+	ID    string
+	Start int
+	End   int
+}
+
+//SubEvent is a USERNOTICE carrying a sub, resub, sub gift, or gift bomb
+type SubEvent struct {
+	Channel          string
+	UserName         string
+	DisplayName      string
+	MsgID            string //"sub", "resub", "subgift", "submysterygift", ...
+	SubPlan          string //"Prime", "1000", "2000", "3000"
+	SubPlanName      string
+	CumulativeMonths int
+	StreakMonths     int
+	SystemMsg        string
+	RecipientUser    string //set for gift subs
+	GiftMonths       int
+	Tags             map[string]string
+}
+
+//RaidEvent is a USERNOTICE announcing an incoming raid
+type RaidEvent struct {
+	Channel     string
+	FromChannel string
+	Viewers     int
+	Tags        map[string]string
+}
+
+//CheerEvent is a PRIVMSG that carried Bits
+type CheerEvent struct {
+	Channel  string
+	UserName string
+	Bits     int
+	Message  *ChatMessage
+}
+
+//TimeoutEvent is a CLEARCHAT targeting a single user
+type TimeoutEvent struct {
+	Channel     string
+	UserName    string
+	BanDuration int //seconds; 0 means a permanent ban rather than a timeout
+	Tags        map[string]string
+}
+
+//ClearMsgEvent is a CLEARMSG deleting a single message
+type ClearMsgEvent struct {
+	Channel     string
+	UserName    string
+	TargetMsgID string
+	Message     string
+}
+
+//RoomState is a ROOMSTATE update describing a channel's chat settings
+type RoomState struct {
+	Channel       string
+	RoomID        string
+	EmoteOnly     bool
+	FollowersOnly int //minutes of required following; -1 means disabled
+	R9K           bool
+	Slow          int //seconds required between messages
+	SubsOnly      bool
+	Tags          map[string]string
+}
+
+//UserState is a USERSTATE update describing the bot's own state in a channel
+type UserState struct {
+	Channel     string
+	DisplayName string
+	Color       string
+	Badges      map[string]string
+	Mod         bool
+	Tags        map[string]string
+}
+
+//NoticeEvent is a server NOTICE, e.g. "This room is now in subscribers-only mode."
+type NoticeEvent struct {
+	Channel string
+	MsgID   string
+	Message string
+}
+
+//HostTargetEvent announces the channel has started or stopped hosting another channel
+type HostTargetEvent struct {
+	Channel       string
+	TargetChannel string //empty once hosting has stopped
+	Viewers       int
+}
+
+//parseTags parses a raw "key=val;key=val" IRCv3 tag string into a map, unescaping values per spec
+func parseTags(raw string) map[string]string {
+	tags := make(map[string]string)
+	if "" == raw {
+		return tags
+	}
+	for _, pair := range strings.Split(raw, ";") {
+		kv := strings.SplitN(pair, "=", 2)
+		if 2 != len(kv) {
+			continue
+		}
+		tags[kv[0]] = unescapeTagValue(kv[1])
+	}
+	return tags
+}
+
+//unescapeTagValue undoes IRCv3 tag value escaping: \s, \:, \\, \r, \n
+func unescapeTagValue(v string) string {
+	replacer := strings.NewReplacer(`\s`, " ", `\:`, ";", `\\`, `\`, `\r`, "\r", `\n`, "\n")
+	return replacer.Replace(v)
+}
+
+//splitTags pulls the leading "@tag1=val1;tag2=val2 " block off an IRCv3 line, if present
+func splitTags(line string) (map[string]string, string) {
+	if !strings.HasPrefix(line, "@") {
+		return nil, line
+	}
+	parts := strings.SplitN(line, " ", 2)
+	if 2 != len(parts) {
+		return parseTags(parts[0][1:]), ""
+	}
+	return parseTags(parts[0][1:]), parts[1]
+}
+
+//parseBadges parses the "badges"/"badge-info" tag format "name/version,name/version" into a map
+func parseBadges(raw string) map[string]string {
+	badges := make(map[string]string)
+	if "" == raw {
+		return badges
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "/", 2)
+		if 2 != len(kv) {
+			continue
+		}
+		badges[kv[0]] = kv[1]
+	}
+	return badges
+}
+
+//parseEmotes parses the "emotes" tag format "id:start-end,start-end/id:start-end" into ranges
+func parseEmotes(raw string) []EmoteRange {
+	var emotes []EmoteRange
+	if "" == raw {
+		return emotes
+	}
+	for _, group := range strings.Split(raw, "/") {
+		kv := strings.SplitN(group, ":", 2)
+		if 2 != len(kv) {
+			continue
+		}
+		id := kv[0]
+		for _, span := range strings.Split(kv[1], ",") {
+			se := strings.SplitN(span, "-", 2)
+			if 2 != len(se) {
+				continue
+			}
+			start, err := strconv.Atoi(se[0])
+			if nil != err {
+				continue
+			}
+			end, err := strconv.Atoi(se[1])
+			if nil != err {
+				continue
+			}
+			emotes = append(emotes, EmoteRange{ID: id, Start: start, End: end})
+		}
+	}
+	return emotes
+}
+
+//parseTmiSentTS converts the "tmi-sent-ts" tag (milliseconds since epoch) to a time.Time
+func parseTmiSentTS(raw string) time.Time {
+	ms, err := strconv.ParseInt(raw, 10, 64)
+	if nil != err {
+		return time.Time{}
+	}
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+//newChatMessage builds a ChatMessage from a parsed PRIVMSG's tags, channel, username, and text
+func newChatMessage(tags map[string]string, channel, userName, text string) *ChatMessage {
+	bits, _ := strconv.Atoi(tags["bits"])
+	return &ChatMessage{
+		Channel:                channel,
+		UserName:               userName,
+		Text:                   text,
+		DisplayName:            tags["display-name"],
+		UserID:                 tags["user-id"],
+		RoomID:                 tags["room-id"],
+		Color:                  tags["color"],
+		Badges:                 parseBadges(tags["badges"]),
+		Bits:                   bits,
+		Emotes:                 parseEmotes(tags["emotes"]),
+		TmiSentTS:              parseTmiSentTS(tags["tmi-sent-ts"]),
+		MsgID:                  tags["id"],
+		ReplyParentMsgID:       tags["reply-parent-msg-id"],
+		ReplyParentUserID:      tags["reply-parent-user-id"],
+		ReplyParentUserLogin:   tags["reply-parent-user-login"],
+		ReplyParentDisplayName: tags["reply-parent-display-name"],
+		ReplyParentMsgBody:     tags["reply-parent-msg-body"],
+		Tags:                   tags,
+	}
+}
+
+//newSubEvent builds a SubEvent from a sub/resub/gift USERNOTICE's tags
+func newSubEvent(tags map[string]string, channel, userName string) *SubEvent {
+	cumulative, _ := strconv.Atoi(tags["msg-param-cumulative-months"])
+	streak, _ := strconv.Atoi(tags["msg-param-streak-months"])
+	giftMonths, _ := strconv.Atoi(tags["msg-param-gift-months"])
+	return &SubEvent{
+		Channel:          channel,
+		UserName:         userName,
+		DisplayName:      tags["display-name"],
+		MsgID:            tags["msg-id"],
+		SubPlan:          tags["msg-param-sub-plan"],
+		SubPlanName:      tags["msg-param-sub-plan-name"],
+		CumulativeMonths: cumulative,
+		StreakMonths:     streak,
+		SystemMsg:        tags["system-msg"],
+		RecipientUser:    tags["msg-param-recipient-user-name"],
+		GiftMonths:       giftMonths,
+		Tags:             tags,
+	}
+}
+
+//newRaidEvent builds a RaidEvent from a raid USERNOTICE's tags
+func newRaidEvent(tags map[string]string, channel string) *RaidEvent {
+	viewers, _ := strconv.Atoi(tags["msg-param-viewerCount"])
+	return &RaidEvent{
+		Channel:     channel,
+		FromChannel: tags["msg-param-login"],
+		Viewers:     viewers,
+		Tags:        tags,
+	}
+}
+
+//newTimeoutEvent builds a TimeoutEvent from a CLEARCHAT's tags
+func newTimeoutEvent(tags map[string]string, channel, userName string) *TimeoutEvent {
+	duration, _ := strconv.Atoi(tags["ban-duration"])
+	return &TimeoutEvent{
+		Channel:     channel,
+		UserName:    userName,
+		BanDuration: duration,
+		Tags:        tags,
+	}
+}
+
+//newClearMsgEvent builds a ClearMsgEvent from a CLEARMSG's tags
+func newClearMsgEvent(tags map[string]string, channel, message string) *ClearMsgEvent {
+	return &ClearMsgEvent{
+		Channel:     channel,
+		UserName:    tags["login"],
+		TargetMsgID: tags["target-msg-id"],
+		Message:     message,
+	}
+}
+
+//followersOnlyAbsent marks a RoomState whose "followers-only" tag was missing from a
+//partial ROOMSTATE update (Twitch only sends the tags that changed), distinct from -1,
+//which is Twitch's own value for "followers-only mode is disabled"
+const followersOnlyAbsent = -2
+
+//newRoomState builds a RoomState from a ROOMSTATE's tags
+func newRoomState(tags map[string]string, channel string) *RoomState {
+	followersOnly, err := strconv.Atoi(tags["followers-only"])
+	if nil != err {
+		followersOnly = followersOnlyAbsent
+	}
+	slow, _ := strconv.Atoi(tags["slow"])
+	return &RoomState{
+		Channel:       channel,
+		RoomID:        tags["room-id"],
+		EmoteOnly:     "1" == tags["emote-only"],
+		FollowersOnly: followersOnly,
+		R9K:           "1" == tags["r9k"],
+		Slow:          slow,
+		SubsOnly:      "1" == tags["subs-only"],
+		Tags:          tags,
+	}
+}
+
+//newUserState builds a UserState from a USERSTATE's tags
+func newUserState(tags map[string]string, channel string) *UserState {
+	return &UserState{
+		Channel:     channel,
+		DisplayName: tags["display-name"],
+		Color:       tags["color"],
+		Badges:      parseBadges(tags["badges"]),
+		Mod:         "1" == tags["mod"],
+		Tags:        tags,
+	}
+}
+
+//newHostTargetEvent builds a HostTargetEvent from a HOSTTARGET line's trailing "<target> <viewers>" text
+func newHostTargetEvent(channel, trailing string) *HostTargetEvent {
+	fields := strings.Fields(trailing)
+	if 0 == len(fields) {
+		return &HostTargetEvent{Channel: channel}
+	}
+	target := fields[0]
+	if "-" == target {
+		target = ""
+	}
+	viewers := -1
+	if len(fields) > 1 {
+		if v, err := strconv.Atoi(fields[1]); nil == err {
+			viewers = v
+		}
+	}
+	return &HostTargetEvent{Channel: channel, TargetChannel: target, Viewers: viewers}
+}
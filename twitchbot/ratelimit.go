@@ -0,0 +1,106 @@
+package twitchbot
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//Twitch's published chat rate limits: https://dev.twitch.tv/docs/irc/guide/#rate-limits
+const (
+	normalMsgCapacity = 20  //messages per msgBucketInterval for a normal user
+	modMsgCapacity    = 100 //messages per msgBucketInterval for a mod/VIP/broadcaster
+	msgBucketInterval = 30 * time.Second
+
+	joinCapacity = 20 //JOINs per joinBucketInterval, shared across all channels
+	joinInterval = 10 * time.Second
+)
+
+//ErrRateLimited is returned by SayNow when no token is currently available
+var ErrRateLimited = errors.New("twitchbot: rate limited")
+
+//tokenBucket is a simple token-bucket rate limiter: it holds up to capacity tokens and
+//refills at a steady rate of capacity tokens per interval
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 //tokens per second
+	last     time.Time
+}
+
+//newTokenBucket returns a tokenBucket that starts full and refills at capacity tokens
+//per interval
+func newTokenBucket(capacity int, interval time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		rate:     float64(capacity) / interval.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+//refill adds tokens earned since the last call, capped at capacity. Callers must hold tb.mu.
+func (tb *tokenBucket) refill() {
+	now := time.Now()
+	tb.tokens += tb.rate * now.Sub(tb.last).Seconds()
+	if tb.tokens > tb.capacity {
+		tb.tokens = tb.capacity
+	}
+	tb.last = now
+}
+
+//TryTake takes one token if one is immediately available, reporting whether it did
+func (tb *tokenBucket) TryTake() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+//Take blocks until a token is available, then takes it
+func (tb *tokenBucket) Take() {
+	for !tb.TryTake() {
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+//msgBucket returns the send-rate bucket for channel, creating a normal-rate one on first use
+func (bb *BasicBot) msgBucket(channel string) *tokenBucket {
+	bb.msgBucketsMu.Lock()
+	defer bb.msgBucketsMu.Unlock()
+	if nil == bb.msgBuckets {
+		bb.msgBuckets = make(map[string]*tokenBucket)
+	}
+	b, ok := bb.msgBuckets[channel]
+	if !ok {
+		b = newTokenBucket(normalMsgCapacity, msgBucketInterval)
+		bb.msgBuckets[channel] = b
+	}
+	return b
+}
+
+//upgradeMsgBucket replaces channel's send-rate bucket with the mod/VIP/broadcaster rate.
+//Called once USERSTATE tells us the bot holds elevated permissions in that channel.
+func (bb *BasicBot) upgradeMsgBucket(channel string) {
+	bb.msgBucketsMu.Lock()
+	defer bb.msgBucketsMu.Unlock()
+	if nil == bb.msgBuckets {
+		bb.msgBuckets = make(map[string]*tokenBucket)
+	}
+	bb.msgBuckets[channel] = newTokenBucket(modMsgCapacity, msgBucketInterval)
+}
+
+//joinTokenBucket returns the bot-wide JOIN rate bucket, creating it on first use
+func (bb *BasicBot) joinTokenBucket() *tokenBucket {
+	bb.joinBucketMu.Lock()
+	defer bb.joinBucketMu.Unlock()
+	if nil == bb.joinBucket {
+		bb.joinBucket = newTokenBucket(joinCapacity, joinInterval)
+	}
+	return bb.joinBucket
+}
@@ -0,0 +1,74 @@
+package twitchbot
+
+import (
+	"fmt"
+	"time"
+)
+
+//EventType identifies which typed field of an Event is populated
+type EventType string
+
+//Event types fanned out to registered sinks
+const (
+	EventTypeMessage    EventType = "message"
+	EventTypeSub        EventType = "sub"
+	EventTypeRaid       EventType = "raid"
+	EventTypeCheer      EventType = "cheer"
+	EventTypeTimeout    EventType = "timeout"
+	EventTypeClearMsg   EventType = "clearmsg"
+	EventTypeHostTarget EventType = "hosttarget"
+)
+
+//Event is the common envelope fanned out to every registered Sink. Exactly one of its
+//typed fields is populated, matching Type. Tags carries the event's full, unparsed
+//IRCv3 tag data so sinks can compute anything not already surfaced on the typed field.
+type Event struct {
+	Type    EventType
+	Channel string
+	Time    time.Time
+	Tags    map[string]string
+
+	Message    *ChatMessage
+	Sub        *SubEvent
+	Raid       *RaidEvent
+	Cheer      *CheerEvent
+	Timeout    *TimeoutEvent
+	ClearMsg   *ClearMsgEvent
+	HostTarget *HostTargetEvent
+}
+
+//Sink receives every chat Event as it happens. Write should return promptly: HandleChat
+//calls every registered sink synchronously and a slow sink delays message handling.
+type Sink interface {
+	Write(Event) error
+	Close() error
+}
+
+//RegisterSink adds s to the set of sinks that receive every Event
+func (bb *BasicBot) RegisterSink(s Sink) {
+	bb.sinksMu.Lock()
+	defer bb.sinksMu.Unlock()
+	bb.sinks = append(bb.sinks, s)
+}
+
+//CloseSinks closes every registered sink, logging any errors
+func (bb *BasicBot) CloseSinks() {
+	bb.sinksMu.RLock()
+	defer bb.sinksMu.RUnlock()
+	for _, s := range bb.sinks {
+		if err := s.Close(); nil != err {
+			fmt.Printf("[%s] sink close error: %v\n", timeStamp(), err)
+		}
+	}
+}
+
+//fireEvent fans ev out to every registered sink
+func (bb *BasicBot) fireEvent(ev Event) {
+	bb.sinksMu.RLock()
+	defer bb.sinksMu.RUnlock()
+	for _, s := range bb.sinks {
+		if err := s.Write(ev); nil != err {
+			fmt.Printf("[%s] sink write error: %v\n", timeStamp(), err)
+		}
+	}
+}
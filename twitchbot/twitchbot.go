@@ -0,0 +1,378 @@
+package twitchbot
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+//backoff bounds for Start's reconnect loop: it begins at minBackoff and doubles on each
+//failed attempt, capped at maxBackoff, and resets to minBackoff once a connection
+//successfully authenticates
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+//ESTFormat is used to define timestamp format
+const ESTFormat = "Jan 2 15:05:05 EST"
+
+//privmsgRegex parses PRIVMSG lines (the only command with a nick!user@host prefix),
+//capturing the sending user, channel, and message text
+var privmsgRegex *regexp.Regexp = regexp.MustCompile(`^:(\w+)!\w+@\w+\.tmi\.twitch\.tv PRIVMSG #(\w+)(?: :(.*))?$`)
+
+//serverMsgRegex parses the other commands, which are sent directly by tmi.twitch.tv:
+//USERNOTICE, CLEARCHAT, CLEARMSG, ROOMSTATE, USERSTATE, NOTICE, HOSTTARGET, RECONNECT
+var serverMsgRegex *regexp.Regexp = regexp.MustCompile(`^:tmi\.twitch\.tv (USERNOTICE|CLEARCHAT|CLEARMSG|ROOMSTATE|USERSTATE|NOTICE|HOSTTARGET|RECONNECT)(?: #(\w+))?(?: :?(.*))?$`)
+
+//TwitchBot interface for accessing chat
+type TwitchBot interface {
+	//Connects to the twitch chat server
+	Connect() error
+	//Disconnects from the twitch chat server
+	Disconnect()
+	//Listens to chat & maintains connection
+	HandleChat() error
+	//Joins a channel's chat in order to access it
+	JoinChannel()
+	//Keeps bot connected and handling chat
+	Start()
+}
+
+//OAuthCred credentials
+type OAuthCred struct {
+	Password string `json:"password,omitempty"`
+}
+
+//BasicBot struct object that does the interacting
+// with the chat
+type BasicBot struct {
+	//Name of the channel to join. Kept for backwards compatibility; prefer Channels
+	//for watching more than one channel over the single connection
+	Channel string
+
+	//Names of the channels to join. One connection watches all of them concurrently
+	Channels []string
+
+	//Path to private json auth token file
+	PrivatePath string
+
+	//TLS connects via tls.Dial instead of a plain net.Dial
+	TLS bool
+
+	//TLSConfig is used for the TLS handshake when TLS is true. If this is nil, a
+	//*tls.Config with only ServerName set to Server is used.
+	TLSConfig *tls.Config
+
+	//Reference to bot's network connection
+	conn net.Conn
+
+	// The credentials necessary for authentication.
+	Credentials *OAuthCred
+
+	//Deprecated: rate limiting is now handled by a per-channel token bucket in front of
+	//Say/Join rather than a flat delay on the read loop. No longer used.
+	MsgRate time.Duration
+
+	//per-channel PRIVMSG send-rate buckets, keyed by channel name
+	msgBuckets   map[string]*tokenBucket
+	msgBucketsMu sync.Mutex
+
+	//bot-wide JOIN-rate bucket
+	joinBucket   *tokenBucket
+	joinBucketMu sync.Mutex
+
+	//Name for the bot to use in chat
+	Name string
+
+	//time that the bot is starting
+	// used for logging
+	startTime time.Time
+
+	//server domain of twitch chat server
+	Server string
+
+	// port for twitch chat server
+	Port string
+
+	//tracked per-channel ROOMSTATE, keyed by channel name
+	channelState   map[string]*ChannelState
+	channelStateMu sync.RWMutex
+
+	//registered command handlers, keyed by name (without the leading "!")
+	commands   map[string]*registeredCommand
+	commandsMu sync.RWMutex
+
+	//registered typed event handlers; append-only, populated via OnMessage/OnSub/OnRaid/OnCheer/OnTimeout
+	onMessage []func(ChatMessage)
+	onSub     []func(SubEvent)
+	onRaid    []func(RaidEvent)
+	onCheer   []func(CheerEvent)
+	onTimeout []func(TimeoutEvent)
+
+	//registered analytics sinks, populated via RegisterSink
+	sinks   []Sink
+	sinksMu sync.RWMutex
+}
+
+//Connect This function will connect the bot to the Twitch Chat server
+func (bb *BasicBot) Connect() error {
+	fmt.Printf("[%s] Connecting to %s...\n", timeStamp(), bb.Server)
+	addr := bb.Server + ":" + bb.Port
+
+	var conn net.Conn
+	var err error
+	if bb.TLS {
+		cfg := bb.TLSConfig
+		if nil == cfg {
+			cfg = &tls.Config{ServerName: bb.Server}
+		}
+		conn, err = tls.Dial("tcp", addr, cfg)
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
+	if nil != err {
+		return fmt.Errorf("bb.Bot.Connect: cannot connect to %s: %w", bb.Server, err)
+	}
+
+	bb.conn = conn
+	return nil
+}
+
+//Disconnect cleanly disconnects the bot from the chat Server
+func (bb *BasicBot) Disconnect() {
+	bb.conn.Close()
+	fmt.Printf("[%s] Closed connection from %s\n", timeStamp(), bb.Server)
+}
+
+//ErrNotAuthenticated is returned by HandleChat when the connection is lost before the
+//server ever confirmed the bot's credentials (no ROOMSTATE/USERSTATE seen). Start checks
+//for this to avoid resetting its backoff against a server that's rejecting auth outright.
+var ErrNotAuthenticated = errors.New("bb.Bot.HandleChat: disconnected before the server authenticated us")
+
+//HandleChat this function does the heavy lifting of handling chat messages
+func (bb *BasicBot) HandleChat() error {
+	fmt.Printf("[%s] Watching #%s...\n", timeStamp(), bb.Channel)
+	bb.registerDefaultCommands()
+	tp := textproto.NewReader(bufio.NewReader(bb.conn))
+
+	//authenticated flips true once the server proves it accepted our credentials, via a
+	//ROOMSTATE or USERSTATE for a channel we joined; Twitch never sends either to a
+	//connection it hasn't authenticated
+	authenticated := false
+
+	for {
+		line, err := tp.ReadLine()
+		if nil != err {
+			bb.Disconnect()
+			if !authenticated {
+				return ErrNotAuthenticated
+			}
+			return errors.New("bb.Bot.HandleChat: Failed to read line from channel. Disconnected")
+		}
+		fmt.Printf("[%s] %s\n", timeStamp(), line)
+		if "PING :tmi.twitch.tv" == line {
+			//maintains connection by replying to PING message from server
+			bb.conn.Write([]byte("PONG: tmi.twitch.tv\r\n"))
+			continue
+		}
+
+		tags, rest := splitTags(line)
+
+		if matches := privmsgRegex.FindStringSubmatch(rest); nil != matches {
+			userName := matches[1]
+			channel := matches[2]
+			text := matches[3]
+			chatMsg := newChatMessage(tags, channel, userName, text)
+			fmt.Printf("[%s] %s: %s\n", timeStamp(), chatMsg.UserName, chatMsg.Text)
+
+			if err := bb.fireMessage(chatMsg); ErrShutdown == err {
+				return nil
+			} else if nil != err {
+				fmt.Printf("[%s] command error: %v\n", timeStamp(), err)
+			}
+		}
+
+		if matches := serverMsgRegex.FindStringSubmatch(rest); nil != matches {
+			cmd, channel, trailing := matches[1], matches[2], matches[3]
+			switch cmd {
+			case "USERNOTICE":
+				switch tags["msg-id"] {
+				case "raid":
+					raid := newRaidEvent(tags, channel)
+					fmt.Printf("[%s] %s raided #%s with %d viewers\n", timeStamp(), raid.FromChannel, channel, raid.Viewers)
+					bb.fireRaid(raid)
+				case "sub", "resub", "subgift", "submysterygift", "anonsubgift", "anonsubmysterygift":
+					sub := newSubEvent(tags, channel, tags["login"])
+					fmt.Printf("[%s] %s\n", timeStamp(), sub.SystemMsg)
+					bb.fireSub(sub)
+				default:
+					fmt.Printf("[%s] %s\n", timeStamp(), tags["system-msg"])
+				}
+			case "CLEARCHAT":
+				if "" != trailing {
+					timeout := newTimeoutEvent(tags, channel, trailing)
+					fmt.Printf("[%s] %s cleared from #%s (duration: %ds)\n", timeStamp(), timeout.UserName, channel, timeout.BanDuration)
+					bb.fireTimeout(timeout)
+				}
+			case "CLEARMSG":
+				clearMsg := newClearMsgEvent(tags, channel, trailing)
+				fmt.Printf("[%s] message from %s deleted in #%s\n", timeStamp(), clearMsg.UserName, channel)
+				bb.fireEvent(Event{Type: EventTypeClearMsg, Channel: channel, Time: parseTmiSentTS(tags["tmi-sent-ts"]), Tags: tags, ClearMsg: clearMsg})
+			case "ROOMSTATE":
+				authenticated = true
+				bb.updateChannelState(newRoomState(tags, channel))
+			case "USERSTATE":
+				authenticated = true
+				us := newUserState(tags, channel)
+				_, broadcaster := us.Badges["broadcaster"]
+				if us.Mod || broadcaster {
+					bb.upgradeMsgBucket(channel)
+				}
+			case "NOTICE":
+				fmt.Printf("[%s] NOTICE #%s: %s\n", timeStamp(), channel, trailing)
+			case "HOSTTARGET":
+				hostTarget := newHostTargetEvent(channel, trailing)
+				bb.fireEvent(Event{Type: EventTypeHostTarget, Channel: channel, Time: parseTmiSentTS(tags["tmi-sent-ts"]), Tags: tags, HostTarget: hostTarget})
+			case "RECONNECT":
+				fmt.Printf("[%s] Twitch requested a reconnect\n", timeStamp())
+				bb.Disconnect()
+				return errors.New("bb.Bot.HandleChat: RECONNECT requested by server")
+			}
+			continue
+		}
+	}
+}
+
+//JoinChannel authenticates the connection and joins every channel currently tracked as
+//joined, or, when none is tracked yet, every channel in bb.Channels (or bb.Channel, for
+//callers that haven't migrated to Channels yet)
+func (bb *BasicBot) JoinChannel() {
+	bb.conn.Write([]byte("PASS " + bb.Credentials.Password + "\r\n"))
+	bb.conn.Write([]byte("NICK " + bb.Name + "\r\n"))
+	//request IRCv3 capabilities so Twitch prefixes messages with tags and emits
+	//USERNOTICE/USERSTATE/ROOMSTATE/CLEARCHAT instead of just PRIVMSG
+	bb.conn.Write([]byte("CAP REQ :twitch.tv/tags twitch.tv/commands twitch.tv/membership\r\n"))
+
+	for _, channel := range bb.rejoinTargets() {
+		bb.Join(channel)
+	}
+}
+
+//ReadCredentials accesses the json auth token and establishes credentials
+func (bb *BasicBot) ReadCredentials() error {
+	credFile, err := ioutil.ReadFile(bb.PrivatePath)
+	if nil != err {
+		return err
+	}
+	bb.Credentials = &OAuthCred{}
+
+	//parse file contents
+	dec := json.NewDecoder(strings.NewReader(string(credFile)))
+	if err = dec.Decode(bb.Credentials); nil != err && io.EOF != err {
+		return err
+	}
+
+	return nil
+}
+
+//Say sends a message to the given channel from the bot's account, blocking until the
+//channel's send-rate bucket has a token available
+func (bb *BasicBot) Say(channel, msg string) error {
+	if "" == msg {
+		return errors.New("BasicBot.Say: msg was empty")
+	}
+	bb.msgBucket(channel).Take()
+	return bb.sendPrivMsg(channel, msg)
+}
+
+//SayNow is Say's non-blocking variant: it sends immediately if the channel's send-rate
+//bucket has a token available, or returns ErrRateLimited without sending if it doesn't
+func (bb *BasicBot) SayNow(channel, msg string) error {
+	if "" == msg {
+		return errors.New("BasicBot.Say: msg was empty")
+	}
+	if !bb.msgBucket(channel).TryTake() {
+		return ErrRateLimited
+	}
+	return bb.sendPrivMsg(channel, msg)
+}
+
+//sendPrivMsg writes the raw PRIVMSG line, bypassing rate limiting
+func (bb *BasicBot) sendPrivMsg(channel, msg string) error {
+	_, err := bb.conn.Write([]byte(fmt.Sprintf("PRIVMSG #%s %s\r\n", channel, msg)))
+	/* Should be irrelevant
+	if nil != err {
+		return err
+	}
+	return nil
+	*/
+	return err
+}
+
+//Start loops calling HandleChat, attempts to reconnect if connection drops or the
+//server sends RECONNECT. Attempts to reconnect, with exponential backoff, until shutdown.
+func (bb *BasicBot) Start() {
+	err := bb.ReadCredentials()
+	if nil != err {
+		fmt.Println(err)
+		fmt.Println("Aborting...")
+		return
+	}
+
+	backoff := minBackoff
+	for {
+		if err := bb.Connect(); nil != err {
+			fmt.Println(err)
+			fmt.Println("Retrying...")
+			backoff = sleepBackoff(backoff)
+			continue
+		}
+		bb.JoinChannel()
+
+		err = bb.HandleChat()
+		if nil == err {
+			return
+		}
+		fmt.Println(err)
+		fmt.Println("Starting again...")
+		if !errors.Is(err, ErrNotAuthenticated) {
+			backoff = minBackoff //reset now that the server has actually authenticated us
+		}
+		backoff = sleepBackoff(backoff)
+	}
+}
+
+//sleepBackoff sleeps for d plus up to 50% jitter, then returns the next backoff duration:
+//d doubled, capped at maxBackoff
+func sleepBackoff(d time.Duration) time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	time.Sleep(d + jitter)
+
+	next := d * 2
+	if next > maxBackoff {
+		next = maxBackoff
+	}
+	return next
+}
+
+//timeStamp is used to return a timestamp in the correct format
+func timeStamp() string {
+	return TimeStamp(ESTFormat)
+}
+
+//TimeStamp calls time function to format time string correctly
+func TimeStamp(format string) string {
+	return time.Now().Format(format)
+}